@@ -1,6 +1,7 @@
 package wasmws
 
 import (
+	"context"
 	"errors"
 	"io"
 	"sync"
@@ -15,6 +16,16 @@ type arrayReader struct {
 
 	read bool
 	err  error
+
+	//streamReader is a ReadableStreamDefaultReader used for large Blobs instead
+	// of buffering the whole message via arrayBuffer(). Zero value when unused.
+	streamReader js.Value
+	streamDone   bool
+
+	//ctx is set via SetContext so that Read and WriteTo both honor the same
+	// deadline/cancellation, since io.Copy calls WriteTo directly and never
+	// goes through Read. ReadContext ignores it and uses only its own ctx.
+	ctx context.Context
 }
 
 var arrayReaderPool = sync.Pool{
@@ -23,6 +34,13 @@ var arrayReaderPool = sync.Pool{
 	},
 }
 
+//blobStreamSupported reports whether the runtime's Blob implements stream(),
+// cached since it cannot change over the lifetime of the wasm module.
+var blobStreamSupported = func() bool {
+	proto := js.Global().Get("Blob").Get("prototype")
+	return proto.Get("stream").Type() == js.TypeFunction
+}()
+
 //newReaderArrayPromise returns a arrayReader from a JavaScript promise for
 // an array buffer: See https://developer.mozilla.org/en-US/docs/Web/API/Blob/arrayBuffer
 func newReaderArrayPromise(arrayPromise js.Value) *arrayReader {
@@ -39,8 +57,27 @@ func newReaderArrayBuffer(arrayBuffer js.Value) (*arrayReader, int) {
 	return ar, len(ar.remaining)
 }
 
+//newReaderBlob returns an arrayReader for a JavaScript Blob, choosing between
+// buffering the whole Blob via arrayBuffer() and streaming it incrementally
+// via Blob.stream(). Blobs at or above socketStreamThresholdBytes are streamed
+// when the runtime supports it, so large WebSocket frames never need to be
+// fully materialized before Read can return data.
+// See: https://developer.mozilla.org/en-US/docs/Web/API/Blob/stream
+func newReaderBlob(blob js.Value) *arrayReader {
+	if blobStreamSupported && blob.Get("size").Int() >= socketStreamThresholdBytes {
+		ar := arrayReaderPool.Get().(*arrayReader)
+		ar.streamReader = blob.Call("stream").Call("getReader")
+		ar.read = true
+		return ar
+	}
+	return newReaderArrayPromise(blob.Call("arrayBuffer"))
+}
+
 //Close closes the arrayReader and returns it to a pool. DO NOT USE FURTHER!
 func (ar *arrayReader) Close() error {
+	if ar.streamReader.Truthy() {
+		ar.streamReader.Call("cancel")
+	}
 	ar.Reset()
 	arrayReaderPool.Put(ar)
 	return nil
@@ -50,6 +87,8 @@ func (ar *arrayReader) Close() error {
 func (ar *arrayReader) Reset() {
 	const bufMax = socketStreamThresholdBytes
 	ar.jsPromise, ar.read, ar.err = js.Value{}, false, nil
+	ar.streamReader, ar.streamDone = js.Value{}, false
+	ar.ctx = nil
 	if cap(ar.remaining) < bufMax {
 		ar.remaining = ar.remaining[:0]
 	} else {
@@ -57,33 +96,47 @@ func (ar *arrayReader) Reset() {
 	}
 }
 
+//SetContext attaches ctx to this arrayReader, so a Conn wrapper can propagate
+// a deadline set via SetReadDeadline to whichever of Read/WriteTo the caller
+// ends up using. Unset, Read and WriteTo behave as if context.Background()
+// had been attached.
+func (ar *arrayReader) SetContext(ctx context.Context) {
+	ar.ctx = ctx
+}
+
+//context returns the context attached via SetContext, defaulting to
+// context.Background().
+func (ar *arrayReader) context() context.Context {
+	if ar.ctx != nil {
+		return ar.ctx
+	}
+	return context.Background()
+}
+
 //Read implements the standard io.Reader interface
 func (ar *arrayReader) Read(buf []byte) (n int, err error) {
+	return ar.ReadContext(ar.context(), buf)
+}
+
+//ReadContext behaves like Read but returns ctx.Err() as soon as ctx is done,
+// rather than blocking until the underlying JS promise settles on its own.
+// It is the hook a Conn wrapper can call from SetReadDeadline to make an
+// in-flight WebSocket message read interruptible. No AbortController is
+// involved: on cancellation the streaming path cancels its stream reader,
+// while the arrayBuffer path stops waiting and leaves the promise pending in
+// JS. Read calls it with the context attached via SetContext, or
+// context.Background() if none was.
+func (ar *arrayReader) ReadContext(ctx context.Context, buf []byte) (n int, err error) {
 	if ar.err != nil {
 		return 0, ar.err
 	}
 
-	if !ar.read {
-		ar.read = true
-		readCh, errCh := make(chan []byte, 1), make(chan error, 1)
-
-		successCallback := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-			readCh <- ar.fromArray(args[0])
-			return nil
-		})
-		defer successCallback.Release()
-
-		failureCallback := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-			errCh <- errors.New(args[0].Get("message").String()) //Send TypeError
-			return nil
-		})
-		defer failureCallback.Release()
-
-		//Wait for callback
-		ar.jsPromise.Call("then", successCallback, failureCallback)
-		select {
-		case ar.remaining = <-readCh:
-		case err := <-errCh:
+	if ar.streamReader.Truthy() {
+		if err := ar.fillFromStream(ctx); err != nil {
+			return 0, err
+		}
+	} else if !ar.read {
+		if err := ar.fillFromPromise(ctx); err != nil {
 			return 0, err
 		}
 	}
@@ -96,11 +149,176 @@ func (ar *arrayReader) Read(buf []byte) (n int, err error) {
 	return n, nil
 }
 
+//awaitJS starts a JS async operation via start (which must arrange for
+// exactly one of the two js.Func it's given to be invoked) and waits for it
+// to settle or ctx to be done. On ctx.Done() it calls onCancel, if non-nil,
+// and returns ctx.Err() without waiting further; start's callbacks are left
+// registered and release themselves whenever they do eventually fire, but by
+// then only hand the raw JS value back over a channel local to this call, so
+// a late fire after the caller has moved on (e.g. this arrayReader was
+// Reset() and handed back out by the pool) can't corrupt anything.
+func (ar *arrayReader) awaitJS(ctx context.Context, start func(success, failure js.Func), onCancel func()) (js.Value, error) {
+	resultCh, errCh := make(chan js.Value, 1), make(chan error, 1)
+
+	var successCallback, failureCallback js.Func
+	successCallback = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		successCallback.Release()
+		failureCallback.Release()
+		resultCh <- args[0]
+		return nil
+	})
+	failureCallback = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		successCallback.Release()
+		failureCallback.Release()
+		errCh <- errors.New(args[0].Get("message").String()) //Send TypeError
+		return nil
+	})
+
+	start(successCallback, failureCallback)
+	select {
+	case result := <-resultCh:
+		return result, nil
+	case err := <-errCh:
+		return js.Value{}, err
+	case <-ctx.Done():
+		if onCancel != nil {
+			onCancel()
+		}
+		return js.Value{}, ctx.Err()
+	}
+}
+
+//fillFromPromise waits for ar.jsPromise to settle into ar.remaining, setting
+// ar.read only once that actually happens: a later Read/ReadContext call
+// will retry rather than seeing a permanent, premature io.EOF if ctx is done
+// first. The Blob.arrayBuffer() promise itself has no AbortController hook,
+// so there's no onCancel to run here.
+func (ar *arrayReader) fillFromPromise(ctx context.Context) error {
+	arrayBuffer, err := ar.awaitJS(ctx, func(success, failure js.Func) {
+		ar.jsPromise.Call("then", success, failure)
+	}, nil)
+	if err != nil {
+		return err
+	}
+	ar.remaining = ar.fromArray(arrayBuffer)
+	ar.read = true
+	return nil
+}
+
+//fillFromStream requests chunks from streamReader until ar.remaining is
+// non-empty or the stream is done, looping past any zero-length, non-final
+// chunk a ReadableStream is free to emit rather than letting ReadContext
+// mistake it for io.EOF.
+func (ar *arrayReader) fillFromStream(ctx context.Context) error {
+	for len(ar.remaining) == 0 && !ar.streamDone {
+		result, err := ar.nextStreamResult(ctx)
+		if err != nil {
+			return err
+		}
+		if result.Get("done").Bool() {
+			ar.streamDone = true
+			return nil
+		}
+		ar.remaining = ar.fromUint8Array(result.Get("value"))
+	}
+	return nil
+}
+
+//nextStreamResult calls streamReader.read() and waits for the {value, done}
+// result it resolves with. On ctx.Done() it calls reader.cancel(), which the
+// Streams API guarantees settles any pending read() promise, so the
+// callbacks release themselves promptly instead of leaking until the stream
+// is otherwise drained.
+func (ar *arrayReader) nextStreamResult(ctx context.Context) (js.Value, error) {
+	return ar.awaitJS(ctx, func(success, failure js.Func) {
+		ar.streamReader.Call("read").Call("then", success, failure)
+	}, func() {
+		ar.streamReader.Call("cancel")
+	})
+}
+
+//WriteTo implements io.WriterTo, letting io.Copy and bufio.Reader.ReadFrom
+// hand the payload straight to w instead of copying it into a caller-supplied
+// buffer first. The arrayBuffer path writes the fully resolved payload in one
+// call; the streaming path writes each chunk to w as it arrives, copying the
+// JS Uint8Array into a reusable scratch slice rather than ar.remaining.
+func (ar *arrayReader) WriteTo(w io.Writer) (int64, error) {
+	if ar.err != nil {
+		return 0, ar.err
+	}
+	if ar.streamReader.Truthy() {
+		return ar.writeToStream(w)
+	}
+	return ar.writeToPromise(w)
+}
+
+func (ar *arrayReader) writeToPromise(w io.Writer) (int64, error) {
+	if !ar.read {
+		if err := ar.fillFromPromise(ar.context()); err != nil {
+			return 0, err
+		}
+	}
+	if len(ar.remaining) == 0 {
+		return 0, nil
+	}
+	n, err := w.Write(ar.remaining)
+	ar.remaining = ar.remaining[n:]
+	return int64(n), err
+}
+
+func (ar *arrayReader) writeToStream(w io.Writer) (int64, error) {
+	var total int64
+	var scratch []byte
+	for {
+		if len(ar.remaining) > 0 {
+			n, err := w.Write(ar.remaining)
+			total += int64(n)
+			ar.remaining = ar.remaining[n:]
+			if err != nil {
+				return total, err
+			}
+			continue
+		}
+		if ar.streamDone {
+			return total, nil
+		}
+
+		result, err := ar.nextStreamResult(ar.context())
+		if err != nil {
+			return total, err
+		}
+		if result.Get("done").Bool() {
+			ar.streamDone = true
+			continue
+		}
+
+		value := result.Get("value")
+		count := value.Get("length").Int()
+		if cap(scratch) < count {
+			scratch = make([]byte, count)
+		} else {
+			scratch = scratch[:count]
+		}
+		js.CopyBytesToGo(scratch, value)
+
+		n, err := w.Write(scratch)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+}
+
 //fromArray is a helper that that copies a JavaScript ArrayBuffer into go-space
 // and uses an existing go buffer if possible.
 func (ar *arrayReader) fromArray(arrayBuffer js.Value) []byte {
-	jsBuf := uint8Array.New(arrayBuffer)
-	count := jsBuf.Get("byteLength").Int()
+	return ar.fromUint8Array(uint8Array.New(arrayBuffer))
+}
+
+//fromUint8Array is a helper that copies a JavaScript Uint8Array into go-space
+// and uses an existing go buffer if possible.
+func (ar *arrayReader) fromUint8Array(jsBuf js.Value) []byte {
+	count := jsBuf.Get("length").Int()
 
 	var goBuf []byte
 	if count <= cap(ar.remaining) {
@@ -0,0 +1,162 @@
+package wasmws
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"syscall/js"
+	"testing"
+	"time"
+)
+
+//newPendingPromise returns a JS Promise together with functions that resolve
+// or reject it, for simulating a Blob.arrayBuffer() promise that settles on
+// the test's own schedule.
+func newPendingPromise() (promise js.Value, resolve, reject func(js.Value)) {
+	var resolveFn, rejectFn js.Value
+	promise = js.Global().Get("Promise").New(js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		resolveFn, rejectFn = args[0], args[1]
+		return nil
+	}))
+	return promise, func(v js.Value) { resolveFn.Invoke(v) }, func(v js.Value) { rejectFn.Invoke(v) }
+}
+
+//TestFillFromPromiseCancelLeavesReaderUsable verifies that a canceled
+//fillFromPromise neither marks ar.read nor lets the eventual, late resolution
+// of the original promise write into an arrayReader that has since been
+// Reset() and reused for a new read.
+func TestFillFromPromiseCancelLeavesReaderUsable(t *testing.T) {
+	promise, resolve, _ := newPendingPromise()
+	ar := newReaderArrayPromise(promise)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := ar.fillFromPromise(ctx); err != context.Canceled {
+		t.Fatalf("fillFromPromise = %v, want context.Canceled", err)
+	}
+	if ar.read {
+		t.Fatalf("ar.read = true after a canceled fill; a later Read would see a premature io.EOF")
+	}
+
+	//Resolve the abandoned promise late, as a real arrayBuffer() might after
+	// its deadline expired. The success callback is still registered.
+	resolve(js.Global().Get("ArrayBuffer").New(4))
+
+	//Reset simulates this arrayReader being Close()'d and handed back out by
+	// the pool for a brand new read while the old promise was still pending.
+	ar.Reset()
+	next, nextResolve, _ := newPendingPromise()
+	ar.jsPromise = next
+	nextResolve(js.Global().Get("ArrayBuffer").New(8))
+
+	//Give both promises' microtasks a chance to run before asserting.
+	time.Sleep(10 * time.Millisecond)
+
+	if err := ar.fillFromPromise(context.Background()); err != nil {
+		t.Fatalf("fillFromPromise on reused arrayReader: %v", err)
+	}
+	if !ar.read {
+		t.Fatalf("ar.read = false after a successful fill")
+	}
+	if len(ar.remaining) != 8 {
+		t.Fatalf("ar.remaining has len %d, want 8 (stale promise corrupted the reused buffer)", len(ar.remaining))
+	}
+}
+
+//newFakeStreamReader returns a JS object shaped like a
+// ReadableStreamDefaultReader, handing out chunks in order via read() and
+// finishing with {done: true}. cancel() is a no-op that resolves immediately.
+func newFakeStreamReader(chunks [][]byte) js.Value {
+	idx := 0
+	reader := js.Global().Get("Object").New()
+	reader.Set("read", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		result := js.Global().Get("Object").New()
+		if idx >= len(chunks) {
+			result.Set("done", true)
+			result.Set("value", js.Undefined())
+		} else {
+			chunk := chunks[idx]
+			idx++
+			value := uint8Array.New(len(chunk))
+			js.CopyBytesToJS(value, chunk)
+			result.Set("done", false)
+			result.Set("value", value)
+		}
+		return js.Global().Get("Promise").Call("resolve", result)
+	}))
+	reader.Set("cancel", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		return js.Global().Get("Promise").Call("resolve", js.Undefined())
+	}))
+	return reader
+}
+
+//newReaderStream returns an arrayReader wired up the same way newReaderBlob
+// wires one for the streaming path, backed by a fake ReadableStreamDefaultReader
+// that yields chunks in order.
+func newReaderStream(chunks [][]byte) *arrayReader {
+	ar := arrayReaderPool.Get().(*arrayReader)
+	ar.streamReader = newFakeStreamReader(chunks)
+	ar.read = true
+	return ar
+}
+
+//TestReadStreamSkipsEmptyIntermediateChunk verifies that a zero-length,
+// non-final chunk from the stream doesn't make Read return io.EOF before the
+// real payload that follows it.
+func TestReadStreamSkipsEmptyIntermediateChunk(t *testing.T) {
+	ar := newReaderStream([][]byte{{}, []byte("hello")})
+
+	buf := make([]byte, 5)
+	n, err := ar.Read(buf)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if string(buf[:n]) != "hello" {
+		t.Fatalf("Read = %q, want %q", buf[:n], "hello")
+	}
+
+	if n, err := ar.Read(buf); err != io.EOF {
+		t.Fatalf("Read at stream end = (%d, %v), want (0, io.EOF)", n, err)
+	}
+}
+
+//TestWriteToStreamSkipsEmptyIntermediateChunk mirrors the Read test above for
+// the WriteTo path, which is what io.Copy actually calls.
+func TestWriteToStreamSkipsEmptyIntermediateChunk(t *testing.T) {
+	ar := newReaderStream([][]byte{[]byte("foo"), {}, []byte("bar")})
+
+	var dst bytes.Buffer
+	n, err := ar.WriteTo(&dst)
+	if err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+	if want := "foobar"; dst.String() != want {
+		t.Fatalf("WriteTo wrote %q, want %q", dst.String(), want)
+	}
+	if n != int64(dst.Len()) {
+		t.Fatalf("WriteTo returned n=%d, want %d", n, dst.Len())
+	}
+}
+
+func TestReadContextRetriesAfterDeadlineExceeded(t *testing.T) {
+	promise, resolve, _ := newPendingPromise()
+	ar := newReaderArrayPromise(promise)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	time.Sleep(5 * time.Millisecond)
+
+	buf := make([]byte, 4)
+	if _, err := ar.ReadContext(ctx, buf); err != context.DeadlineExceeded {
+		t.Fatalf("ReadContext = %v, want context.DeadlineExceeded", err)
+	}
+
+	resolve(js.Global().Get("ArrayBuffer").New(4))
+	n, err := ar.ReadContext(context.Background(), buf)
+	if err != nil {
+		t.Fatalf("ReadContext after deadline: %v", err)
+	}
+	if n != 4 {
+		t.Fatalf("ReadContext after deadline returned n=%d, want 4", n)
+	}
+}
@@ -0,0 +1,148 @@
+//Package protoio layers varint length-prefixed message framing on top of a
+// net.Conn, analogous to gogo/protobuf's protoio delimited reader/writer.
+// It lets multiple protobuf messages be packed into a single WebSocket
+// binary frame without callers having to reinvent framing on every Read.
+package protoio
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+
+	"github.com/gogo/protobuf/proto"
+)
+
+//ErrMsgTooLarge is returned when a delimited message's declared length
+// exceeds the maxSize passed to NewDelimitedReader. Unlike ErrShortBuffer,
+// this leaves the unread payload on conn: draining an attacker-controlled
+// length just to resync would reintroduce the resource-exhaustion problem
+// maxSize exists to prevent, so conn should be treated as unusable and
+// closed by the caller after this error.
+var ErrMsgTooLarge = errors.New("protoio: message length exceeds maxSize")
+
+//DelimitedReader is an io.ReadCloser with a ReadMsg convenience for decoding
+// the next length-delimited message directly into a proto.Message.
+type DelimitedReader interface {
+	io.ReadCloser
+	//ReadMsg reads the next length-delimited message from conn and unmarshals
+	// it into msg.
+	ReadMsg(msg proto.Message) error
+}
+
+type delimitedReader struct {
+	conn    net.Conn
+	maxSize int
+	byteBuf [1]byte
+}
+
+//NewDelimitedReader returns a DelimitedReader that reads varint
+// length-prefixed messages from conn, rejecting any whose declared length
+// exceeds maxSize to guard against OOM from a malformed or hostile peer.
+func NewDelimitedReader(conn net.Conn, maxSize int) DelimitedReader {
+	return &delimitedReader{conn: conn, maxSize: maxSize}
+}
+
+//ReadByte implements io.ByteReader by reading exactly one byte from conn, so
+// the varint length prefix is consumed without over-reading into the next
+// message packed into the same WebSocket frame.
+func (r *delimitedReader) ReadByte() (byte, error) {
+	if _, err := io.ReadFull(r.conn, r.byteBuf[:]); err != nil {
+		return 0, err
+	}
+	return r.byteBuf[0], nil
+}
+
+//Read reads one length-delimited message into p, returning
+// io.ErrShortBuffer if p is too small to hold it. The message's payload is
+// still fully drained from conn in that case (length is already bounded by
+// maxSize, so this can't be abused to make Read block on an unbounded
+// amount of data), keeping framing in sync for the next Read/ReadMsg call.
+func (r *delimitedReader) Read(p []byte) (int, error) {
+	length, err := r.readLength()
+	if err != nil {
+		return 0, err
+	}
+	if uint64(len(p)) < length {
+		if _, err := io.CopyN(io.Discard, r.conn, int64(length)); err != nil {
+			return 0, err
+		}
+		return 0, io.ErrShortBuffer
+	}
+	if _, err := io.ReadFull(r.conn, p[:length]); err != nil {
+		return 0, err
+	}
+	return int(length), nil
+}
+
+//ReadMsg reads one length-delimited message and unmarshals it into msg.
+func (r *delimitedReader) ReadMsg(msg proto.Message) error {
+	length, err := r.readLength()
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(r.conn, buf); err != nil {
+		return err
+	}
+	return proto.Unmarshal(buf, msg)
+}
+
+//readLength decodes the varint length prefix one byte at a time and
+// validates it against maxSize before any payload bytes are read.
+func (r *delimitedReader) readLength() (uint64, error) {
+	length, err := binary.ReadUvarint(r)
+	if err != nil {
+		return 0, err
+	}
+	if length > uint64(r.maxSize) {
+		return 0, ErrMsgTooLarge
+	}
+	return length, nil
+}
+
+//Close is a no-op: the reader returned by NewDelimitedReader typically shares
+// conn with a writer layered over the same connection, so closing one must
+// not pull the rug out from under the other. The caller owns conn and is
+// responsible for closing it once both sides are done with it.
+func (r *delimitedReader) Close() error {
+	return nil
+}
+
+type delimitedWriter struct {
+	conn   net.Conn
+	lenBuf []byte
+	buf    []byte
+}
+
+//NewDelimitedWriter returns an io.WriteCloser that prefixes each Write with a
+// varint length header, so multiple protobuf messages can be packed into one
+// WebSocket binary frame.
+func NewDelimitedWriter(conn net.Conn) io.WriteCloser {
+	return &delimitedWriter{conn: conn, lenBuf: make([]byte, binary.MaxVarintLen64)}
+}
+
+//Write assembles the length prefix and p into a single conn.Write call. Two
+// separate writes would let a length prefix reach the wire and then fail (or
+// get retried) independently of its payload, desyncing the framing for every
+// message after it; one write either lands both together or neither.
+func (w *delimitedWriter) Write(p []byte) (int, error) {
+	n := binary.PutUvarint(w.lenBuf, uint64(len(p)))
+	if cap(w.buf) < n+len(p) {
+		w.buf = make([]byte, n+len(p))
+	} else {
+		w.buf = w.buf[:n+len(p)]
+	}
+	copy(w.buf, w.lenBuf[:n])
+	copy(w.buf[n:], p)
+
+	if _, err := w.conn.Write(w.buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+//Close is a no-op; see delimitedReader.Close for why this doesn't close conn.
+func (w *delimitedWriter) Close() error {
+	return nil
+}
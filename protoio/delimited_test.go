@@ -0,0 +1,156 @@
+package protoio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"testing"
+)
+
+// fakeConn is a minimal net.Conn backed by an in-memory buffer, enough to
+// exercise framing without pulling in a real socket.
+type fakeConn struct {
+	net.Conn
+	r      *bytes.Buffer
+	w      *bytes.Buffer
+	closed bool
+}
+
+func (c *fakeConn) Read(p []byte) (int, error)  { return c.r.Read(p) }
+func (c *fakeConn) Write(p []byte) (int, error) { return c.w.Write(p) }
+func (c *fakeConn) Close() error                { c.closed = true; return nil }
+
+func newFakeConn() *fakeConn {
+	return &fakeConn{r: new(bytes.Buffer), w: new(bytes.Buffer)}
+}
+
+func TestDelimitedRoundTrip(t *testing.T) {
+	conn := newFakeConn()
+	w := NewDelimitedWriter(conn)
+
+	msgs := [][]byte{[]byte("hello"), []byte(""), bytes.Repeat([]byte("x"), 1024)}
+	for _, m := range msgs {
+		if _, err := w.Write(m); err != nil {
+			t.Fatalf("Write(%q): %v", m, err)
+		}
+	}
+	conn.r = conn.w // feed writer's output back in as the reader's input
+
+	r := NewDelimitedReader(conn, 4096)
+	for _, want := range msgs {
+		got := make([]byte, len(want))
+		n, err := r.Read(got)
+		if err != nil {
+			t.Fatalf("Read: %v", err)
+		}
+		if !bytes.Equal(got[:n], want) {
+			t.Fatalf("Read = %q, want %q", got[:n], want)
+		}
+	}
+}
+
+func TestDelimitedReaderShortBufferDrainsAndResyncs(t *testing.T) {
+	conn := newFakeConn()
+	w := NewDelimitedWriter(conn)
+	if _, err := w.Write([]byte("too big for the caller's buffer")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if _, err := w.Write([]byte("next")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	conn.r = conn.w
+
+	r := NewDelimitedReader(conn, 4096)
+	small := make([]byte, 4)
+	if _, err := r.Read(small); err != io.ErrShortBuffer {
+		t.Fatalf("Read = %v, want io.ErrShortBuffer", err)
+	}
+
+	next := make([]byte, 16)
+	n, err := r.Read(next)
+	if err != nil {
+		t.Fatalf("Read after short buffer: %v", err)
+	}
+	if string(next[:n]) != "next" {
+		t.Fatalf("Read after short buffer = %q, want %q (stream desynced)", next[:n], "next")
+	}
+}
+
+func TestDelimitedReaderRejectsOversizedMessage(t *testing.T) {
+	conn := newFakeConn()
+	w := NewDelimitedWriter(conn)
+	if _, err := w.Write(bytes.Repeat([]byte("x"), 100)); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	conn.r = conn.w
+
+	r := NewDelimitedReader(conn, 10)
+	if _, err := r.Read(make([]byte, 100)); err != ErrMsgTooLarge {
+		t.Fatalf("Read = %v, want ErrMsgTooLarge", err)
+	}
+}
+
+func TestDelimitedReadHandBuiltLengthPrefix(t *testing.T) {
+	conn := newFakeConn()
+	payload := []byte("framed")
+	lenBuf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(lenBuf, uint64(len(payload)))
+	conn.r.Write(lenBuf[:n])
+	conn.r.Write(payload)
+
+	r := NewDelimitedReader(conn, 4096)
+	got := make([]byte, len(payload))
+	if n, err := r.Read(got); err != nil || string(got[:n]) != string(payload) {
+		t.Fatalf("Read = (%q, %v), want (%q, nil)", got[:n], err, payload)
+	}
+}
+
+// oneShotFailConn counts Write calls and fails every one of them, to verify
+// that a failing conn only ever sees a single Write per delimitedWriter.Write
+// rather than a length prefix and payload sent as two independent writes.
+type oneShotFailConn struct {
+	*fakeConn
+	calls int
+}
+
+func (c *oneShotFailConn) Write(p []byte) (int, error) {
+	c.calls++
+	return 0, errors.New("write failed")
+}
+
+func TestDelimitedWriterSingleWriteOnFailure(t *testing.T) {
+	failing := &oneShotFailConn{fakeConn: newFakeConn()}
+	w := NewDelimitedWriter(failing)
+
+	if _, err := w.Write([]byte("hello")); err == nil {
+		t.Fatalf("Write: want error")
+	}
+	if failing.calls != 1 {
+		t.Fatalf("conn.Write called %d times, want 1 (length prefix and payload must reach the wire atomically)", failing.calls)
+	}
+}
+
+func TestDelimitedCloseDoesNotCloseConn(t *testing.T) {
+	conn := newFakeConn()
+	r := NewDelimitedReader(conn, 4096)
+	w := NewDelimitedWriter(conn)
+
+	if err := r.Close(); err != nil {
+		t.Fatalf("Reader Close: %v", err)
+	}
+	if conn.closed {
+		t.Fatalf("Reader.Close closed the shared conn")
+	}
+	// Writer must still work after the reader sharing conn is closed.
+	if _, err := w.Write([]byte("still alive")); err != nil {
+		t.Fatalf("Write after Reader.Close: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Writer Close: %v", err)
+	}
+	if conn.closed {
+		t.Fatalf("Writer.Close closed the shared conn")
+	}
+}